@@ -20,6 +20,8 @@ type FlowMarketCreated struct {
 	Creator        string      `gorm:"column:creator;index" json:"creator"`
 	BlockHeight    uint64      `gorm:"column:block_height;index" json:"blockHeight"`
 	BlockTimestamp int64       `gorm:"column:block_timestamp;index" json:"blockTimestamp"`
+	BlockID        string      `gorm:"column:block_id;index" json:"blockId"`
+	Finalized      bool        `gorm:"column:finalized;index" json:"finalized"`
 	TransactionID  string      `gorm:"column:transaction_id;index" json:"transactionId"`
 	EventIndex     uint32      `gorm:"column:event_index" json:"eventIndex"`
 	CreatedAt      time.Time   `gorm:"autoCreateTime" json:"createdAt"`
@@ -38,6 +40,8 @@ type FlowBetPlaced struct {
 	Amount         string    `gorm:"column:amount" json:"amount"`
 	BlockHeight    uint64    `gorm:"column:block_height;index" json:"blockHeight"`
 	BlockTimestamp int64     `gorm:"column:block_timestamp;index" json:"blockTimestamp"`
+	BlockID        string    `gorm:"column:block_id;index" json:"blockId"`
+	Finalized      bool      `gorm:"column:finalized;index" json:"finalized"`
 	TransactionID  string    `gorm:"column:transaction_id;index" json:"transactionId"`
 	EventIndex     uint32    `gorm:"column:event_index" json:"eventIndex"`
 	CreatedAt      time.Time `gorm:"autoCreateTime" json:"createdAt"`
@@ -55,6 +59,8 @@ type FlowMarketResolved struct {
 	ResolvedAt     string    `gorm:"column:resolved_at" json:"resolvedAt"`
 	BlockHeight    uint64    `gorm:"column:block_height;index" json:"blockHeight"`
 	BlockTimestamp int64     `gorm:"column:block_timestamp;index" json:"blockTimestamp"`
+	BlockID        string    `gorm:"column:block_id;index" json:"blockId"`
+	Finalized      bool      `gorm:"column:finalized;index" json:"finalized"`
 	TransactionID  string    `gorm:"column:transaction_id;index" json:"transactionId"`
 	EventIndex     uint32    `gorm:"column:event_index" json:"eventIndex"`
 	CreatedAt      time.Time `gorm:"autoCreateTime" json:"createdAt"`
@@ -71,6 +77,8 @@ type FlowWinningsClaimed struct {
 	Payout         string `gorm:"column:payout" json:"payout"`
 	BlockHeight    uint64 `gorm:"column:block_height;index" json:"blockHeight"`
 	BlockTimestamp int64  `gorm:"column:block_timestamp;index" json:"blockTimestamp"`
+	BlockID        string `gorm:"column:block_id;index" json:"blockId"`
+	Finalized      bool   `gorm:"column:finalized;index" json:"finalized"`
 	TransactionID  string `gorm:"column:transaction_id;index" json:"transactionId"`
 	EventIndex     uint32 `gorm:"column:event_index" json:"eventIndex"`
 }
@@ -87,6 +95,8 @@ type FlowYieldDeposited struct {
 	PositionID     string `gorm:"column:position_id" json:"positionId"`
 	BlockHeight    uint64 `gorm:"column:block_height;index" json:"blockHeight"`
 	BlockTimestamp int64  `gorm:"column:block_timestamp;index" json:"blockTimestamp"`
+	BlockID        string `gorm:"column:block_id;index" json:"blockId"`
+	Finalized      bool   `gorm:"column:finalized;index" json:"finalized"`
 	TransactionID  string `gorm:"column:transaction_id;index" json:"transactionId"`
 	EventIndex     uint32 `gorm:"column:event_index" json:"eventIndex"`
 }
@@ -103,6 +113,8 @@ type FlowYieldWithdrawn struct {
 	YieldEarned    string `gorm:"column:yield_earned" json:"yieldEarned"`
 	BlockHeight    uint64 `gorm:"column:block_height;index" json:"blockHeight"`
 	BlockTimestamp int64  `gorm:"column:block_timestamp;index" json:"blockTimestamp"`
+	BlockID        string `gorm:"column:block_id;index" json:"blockId"`
+	Finalized      bool   `gorm:"column:finalized;index" json:"finalized"`
 	TransactionID  string `gorm:"column:transaction_id;index" json:"transactionId"`
 	EventIndex     uint32 `gorm:"column:event_index" json:"eventIndex"`
 }
@@ -124,6 +136,22 @@ func (FlowSyncState) TableName() string {
 	return "flow_sync_states"
 }
 
+// FlowBlockHeader records the last few blocks the indexer has walked over,
+// keyed by height, so a reorg can be detected by comparing a newly observed
+// block's ParentID against the header already stored at height-1.
+type FlowBlockHeader struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Height    uint64    `gorm:"uniqueIndex;column:height" json:"height"`
+	BlockID   string    `gorm:"column:block_id;index" json:"blockId"`
+	ParentID  string    `gorm:"column:parent_id" json:"parentId"`
+	Finalized bool      `gorm:"column:finalized;index" json:"finalized"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+func (FlowBlockHeader) TableName() string {
+	return "flow_block_headers"
+}
+
 type StringArray []string
 
 func (a StringArray) Value() (driver.Value, error) {
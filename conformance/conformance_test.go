@@ -0,0 +1,178 @@
+package conformance
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/evaafi/go-indexer/handlers"
+)
+
+// update regenerates every vector's golden expectedPayload/expectedRow
+// from the handler's actual output instead of asserting against it. Run
+// with `go test ./conformance/... -update` after an intentional decode or
+// storage change.
+var update = flag.Bool("update", false, "regenerate conformance golden files")
+
+// TestConformance decodes and stores every recorded vector through the
+// same handlers.EventHandler path the real indexer uses, and checks the
+// result against the golden payload/row recorded alongside the vector.
+// It exists to turn Cadence field-name drift (an event quietly renaming
+// "protocols" to "options", or "user" to "userAddress") into a test
+// failure instead of a silently wrong or missing column.
+func TestConformance(t *testing.T) {
+	if os.Getenv("SKIP_CONFORMANCE") == "1" {
+		t.Skip("SKIP_CONFORMANCE=1")
+	}
+
+	dir, err := vectorsDir()
+	if err != nil {
+		t.Fatalf("resolving vectors dir: %v", err)
+	}
+
+	files, err := loadVectorFiles(dir)
+	if err != nil {
+		t.Fatalf("listing vectors: %v", err)
+	}
+
+	if len(files) == 0 {
+		t.Fatal("no conformance vectors found")
+	}
+
+	for _, path := range files {
+		path := path
+
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			runVector(t, path)
+		})
+	}
+}
+
+func runVector(t *testing.T, path string) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s: %v", path, err)
+	}
+
+	var vec Vector
+	if err := json.Unmarshal(raw, &vec); err != nil {
+		t.Fatalf("parsing %s: %v", path, err)
+	}
+
+	handler, ok := handlerFor[vec.EventType]
+	if !ok {
+		t.Fatalf("%s: no handler registered for event type %q", path, vec.EventType)
+	}
+
+	value, err := jsoncdc.Decode(nil, vec.Raw)
+	if err != nil {
+		t.Fatalf("%s: decoding JSON-CDC: %v", path, err)
+	}
+
+	event, ok := value.(cadence.Event)
+	if !ok {
+		t.Fatalf("%s: raw vector decoded to %T, not cadence.Event", path, value)
+	}
+
+	payload, err := handler.Decode(event)
+	if err != nil {
+		t.Fatalf("%s: Decode: %v", path, err)
+	}
+
+	payloadJSON, payloadCanon, err := marshalCanonical(payload)
+	if err != nil {
+		t.Fatalf("%s: marshaling decoded payload: %v", path, err)
+	}
+
+	row := storeVector(t, path, handler, payload, vec.Meta)
+
+	rowJSON, rowCanon, err := marshalCanonical(row)
+	if err != nil {
+		t.Fatalf("%s: marshaling stored row: %v", path, err)
+	}
+
+	if *update {
+		vec.ExpectedPayload = payloadJSON
+		vec.ExpectedRow = rowJSON
+		writeVector(t, path, vec)
+
+		return
+	}
+
+	wantPayload, err := canonicalJSON(vec.ExpectedPayload)
+	if err != nil {
+		t.Fatalf("%s: parsing expectedPayload: %v", path, err)
+	}
+
+	if payloadCanon != wantPayload {
+		t.Errorf("%s: decoded payload mismatch\ngot:\n%s\nwant:\n%s", path, payloadCanon, wantPayload)
+	}
+
+	wantRow, err := canonicalJSON(vec.ExpectedRow)
+	if err != nil {
+		t.Fatalf("%s: parsing expectedRow: %v", path, err)
+	}
+
+	if rowCanon != wantRow {
+		t.Errorf("%s: stored row mismatch\ngot:\n%s\nwant:\n%s", path, rowCanon, wantRow)
+	}
+}
+
+func storeVector(t *testing.T, path string, handler handlers.EventHandler, payload any, meta VectorMeta) map[string]any {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("%s: opening in-memory db: %v", path, err)
+	}
+
+	model, ok := modelFor(handler.EventType())
+	if !ok {
+		t.Fatalf("%s: no row model for event type %q", path, handler.EventType())
+	}
+
+	if err := db.AutoMigrate(model); err != nil {
+		t.Fatalf("%s: migrating: %v", path, err)
+	}
+
+	decoded := handlers.DecodedEvent{
+		Payload:        payload,
+		BlockHeight:    meta.BlockHeight,
+		BlockTimestamp: meta.BlockTimestamp,
+		BlockID:        meta.BlockID,
+		Finalized:      meta.Finalized,
+		TransactionID:  meta.TransactionID,
+		EventIndex:     meta.EventIndex,
+	}
+
+	if err := handler.Store(db, decoded); err != nil {
+		t.Fatalf("%s: Store: %v", path, err)
+	}
+
+	row, err := lastRow(db, handler.EventType())
+	if err != nil {
+		t.Fatalf("%s: reading stored row: %v", path, err)
+	}
+
+	return row
+}
+
+func writeVector(t *testing.T, path string, vec Vector) {
+	t.Helper()
+
+	out, err := json.MarshalIndent(vec, "", "  ")
+	if err != nil {
+		t.Fatalf("%s: marshaling updated vector: %v", path, err)
+	}
+
+	if err := os.WriteFile(path, append(out, '\n'), 0o644); err != nil {
+		t.Fatalf("%s: writing updated vector: %v", path, err)
+	}
+}
@@ -0,0 +1,69 @@
+package conformance
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/evaafi/go-indexer/config"
+	"github.com/evaafi/go-indexer/handlers"
+)
+
+// handlerFor maps every event type the conformance suite knows about to
+// the concrete handlers.EventHandler that decodes and stores it. Kept as
+// a plain map (rather than handlers.NewHandlerRegistry) since a vector's
+// EventType is already the short name handlers key on, with no contract
+// address to fold in.
+var handlerFor = map[string]handlers.EventHandler{
+	"MarketCreated":   handlers.MarketCreatedHandler{},
+	"BetPlaced":       handlers.BetPlacedHandler{},
+	"MarketResolved":  handlers.MarketResolvedHandler{},
+	"WinningsClaimed": handlers.WinningsClaimedHandler{},
+	"YieldDeposited":  handlers.YieldDepositedHandler{},
+	"YieldWithdrawn":  handlers.YieldWithdrawnHandler{},
+}
+
+// modelFor returns a fresh, empty row model for eventType so the harness
+// can AutoMigrate its table and read the row Store wrote back out.
+func modelFor(eventType string) (any, bool) {
+	switch eventType {
+	case "MarketCreated":
+		return &config.FlowMarketCreated{}, true
+	case "BetPlaced":
+		return &config.FlowBetPlaced{}, true
+	case "MarketResolved":
+		return &config.FlowMarketResolved{}, true
+	case "WinningsClaimed":
+		return &config.FlowWinningsClaimed{}, true
+	case "YieldDeposited":
+		return &config.FlowYieldDeposited{}, true
+	case "YieldWithdrawn":
+		return &config.FlowYieldWithdrawn{}, true
+	default:
+		return nil, false
+	}
+}
+
+// lastRow fetches the most recently inserted row for eventType as a plain
+// map, dropping the columns Store doesn't derive from the vector itself
+// (auto-increment ID, auto-set CreatedAt) so golden files stay stable.
+func lastRow(db *gorm.DB, eventType string) (map[string]any, error) {
+	model, ok := modelFor(eventType)
+	if !ok {
+		return nil, errUnknownEventType(eventType)
+	}
+
+	var row map[string]any
+	if err := db.Model(model).Order("id desc").Limit(1).Find(&row).Error; err != nil {
+		return nil, err
+	}
+
+	delete(row, "id")
+	delete(row, "created_at")
+
+	return row, nil
+}
+
+type errUnknownEventType string
+
+func (e errUnknownEventType) Error() string {
+	return "conformance: unknown event type " + string(e)
+}
@@ -0,0 +1,78 @@
+// Package conformance holds the indexer's Cadence event decoding
+// conformance suite: a corpus of recorded flow.Event payloads, each
+// paired with the payload and database row the indexer is expected to
+// produce from it. The suite exists to catch Cadence field-name drift
+// (e.g. an event renaming "protocols" to "options", or "user" to
+// "userAddress") as a loud test failure instead of a silently-dropped
+// field.
+package conformance
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Vector is one recorded conformance case, stored as vectors/<name>.json.
+// Raw is the JSON-CDC encoding of a flow.Event (https://cadence-lang.org,
+// "JSON-CDC"), exactly as the Access API would deliver it. ExpectedPayload
+// and ExpectedRow are canonical-JSON snapshots of the handler's Decode
+// output and the row it Stores, respectively.
+type Vector struct {
+	// Name is a human-readable description of the vector. The harness does
+	// not read it: subtests and failure messages use the vector's file name.
+	Name string `json:"name"`
+
+	// EventType is the short Cadence event name (e.g. "MarketCreated"),
+	// matching some handlers.EventHandler's EventType().
+	EventType string `json:"eventType"`
+
+	Raw json.RawMessage `json:"raw"`
+
+	// Meta fills in the block/transaction context a real Ingestor would
+	// have attached; Decode never sees it, but Store does.
+	Meta VectorMeta `json:"meta"`
+
+	ExpectedPayload json.RawMessage `json:"expectedPayload"`
+	ExpectedRow     json.RawMessage `json:"expectedRow"`
+}
+
+// VectorMeta mirrors handlers.DecodedEvent, minus Payload.
+type VectorMeta struct {
+	BlockHeight    uint64 `json:"blockHeight"`
+	BlockTimestamp int64  `json:"blockTimestamp"`
+	BlockID        string `json:"blockId"`
+	Finalized      bool   `json:"finalized"`
+	TransactionID  string `json:"transactionId"`
+	EventIndex     uint32 `json:"eventIndex"`
+}
+
+// canonicalJSON re-marshals v through an empty interface so two
+// differently-formatted (but structurally equal) JSON documents compare
+// equal as strings.
+func canonicalJSON(raw json.RawMessage) (string, error) {
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return "", fmt.Errorf("unmarshaling: %w", err)
+	}
+
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshaling: %w", err)
+	}
+
+	return string(out), nil
+}
+
+func marshalCanonical(v any) (json.RawMessage, string, error) {
+	out, err := json.Marshal(v)
+	if err != nil {
+		return nil, "", fmt.Errorf("marshaling: %w", err)
+	}
+
+	canon, err := canonicalJSON(out)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return out, canon, nil
+}
@@ -0,0 +1,56 @@
+package conformance
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// vectorsRepo is the external corpus cloned when VECTORS_BRANCH is set,
+// letting the Trixy protocol team and the indexer team iterate on
+// recorded vectors independently of this repo's release cycle.
+const vectorsRepo = "https://github.com/TrixyProtocol/event-conformance-vectors.git"
+
+// vectorsDir resolves the directory conformance vectors should be loaded
+// from: a shallow clone of vectorsRepo at VECTORS_BRANCH if that env var
+// is set, otherwise the vectors/ directory checked into this package.
+func vectorsDir() (string, error) {
+	branch := os.Getenv("VECTORS_BRANCH")
+	if branch == "" {
+		return "vectors", nil
+	}
+
+	dir, err := os.MkdirTemp("", "trixy-conformance-vectors-")
+	if err != nil {
+		return "", fmt.Errorf("creating temp dir for %s: %w", vectorsRepo, err)
+	}
+
+	cmd := exec.Command("git", "clone", "--depth", "1", "--branch", branch, vectorsRepo, dir)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("cloning %s@%s: %w\n%s", vectorsRepo, branch, err, out)
+	}
+
+	return dir, nil
+}
+
+// loadVectorFiles lists every *.json vector in dir, sorted by filename so
+// test output and -update diffs stay stable across runs.
+func loadVectorFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading vectors dir %s: %w", dir, err)
+	}
+
+	var files []string
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		files = append(files, filepath.Join(dir, entry.Name()))
+	}
+
+	return files, nil
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/onflow/cadence"
+	"gorm.io/gorm"
+
+	"github.com/evaafi/go-indexer/config"
+)
+
+type betPlacedPayload struct {
+	MarketID       uint64
+	User           string
+	SelectedOption string
+	ProtocolIndex  uint32
+	Amount         string
+}
+
+// BetPlacedHandler decodes and stores TrixyEvents.BetPlaced.
+type BetPlacedHandler struct{}
+
+func (BetPlacedHandler) EventType() string { return "BetPlaced" }
+
+func (BetPlacedHandler) Decode(event cadence.Event) (any, error) {
+	fields := cadence.FieldsMappedByName(event)
+
+	// Extract protocolIndex, default to 0 if not present
+	protocolIndex := uint32(0)
+	if protocolIndexField := fields["protocolIndex"]; protocolIndexField != nil {
+		protocolIndex = uint32(protocolIndexField.(cadence.UInt32))
+	}
+
+	return betPlacedPayload{
+		MarketID:       uint64(fields["marketId"].(cadence.UInt64)),
+		User:           fields["user"].(cadence.Address).String(),
+		SelectedOption: string(fields["selectedOption"].(cadence.String)),
+		ProtocolIndex:  protocolIndex,
+		Amount:         fields["amount"].(cadence.UFix64).String(),
+	}, nil
+}
+
+func (BetPlacedHandler) Fields(payload any) map[string]any {
+	p, ok := payload.(betPlacedPayload)
+	if !ok {
+		return nil
+	}
+
+	return map[string]any{
+		"marketId": p.MarketID,
+		"user":     p.User,
+	}
+}
+
+func (BetPlacedHandler) Store(db *gorm.DB, decoded DecodedEvent) error {
+	payload, ok := decoded.Payload.(betPlacedPayload)
+	if !ok {
+		return fmt.Errorf("unexpected payload type %T for BetPlaced", decoded.Payload)
+	}
+
+	return db.Create(&config.FlowBetPlaced{
+		MarketID:       payload.MarketID,
+		User:           payload.User,
+		SelectedOption: payload.SelectedOption,
+		ProtocolIndex:  payload.ProtocolIndex,
+		Amount:         payload.Amount,
+		BlockHeight:    decoded.BlockHeight,
+		BlockTimestamp: decoded.BlockTimestamp,
+		BlockID:        decoded.BlockID,
+		Finalized:      decoded.Finalized,
+		TransactionID:  decoded.TransactionID,
+		EventIndex:     decoded.EventIndex,
+	}).Error
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/onflow/cadence"
+	"gorm.io/gorm"
+
+	"github.com/evaafi/go-indexer/config"
+)
+
+type marketCreatedPayload struct {
+	MarketID      uint64
+	Question      string
+	EndTime       string
+	Options       []string
+	YieldProtocol string
+	Creator       string
+}
+
+// MarketCreatedHandler decodes and stores TrixyEvents.MarketCreated.
+type MarketCreatedHandler struct{}
+
+func (MarketCreatedHandler) EventType() string { return "MarketCreated" }
+
+func (MarketCreatedHandler) Decode(event cadence.Event) (any, error) {
+	fields := cadence.FieldsMappedByName(event)
+
+	options := []string{}
+
+	optionsField := fields["options"]
+	if optionsField == nil {
+		optionsField = fields["protocols"]
+	}
+
+	if optionsField != nil {
+		array, ok := optionsField.(cadence.Array)
+		if !ok {
+			return nil, fmt.Errorf("options field has unexpected type %T", optionsField)
+		}
+
+		for _, p := range array.Values {
+			options = append(options, string(p.(cadence.String)))
+		}
+	}
+
+	endTimeUFix64, ok := fields["endTime"].(cadence.UFix64)
+	if !ok {
+		return nil, fmt.Errorf("endTime field has unexpected type %T", fields["endTime"])
+	}
+
+	yieldProtocol := ""
+	if yieldProtoField := fields["yieldProtocol"]; yieldProtoField != nil {
+		yieldProtocol = string(yieldProtoField.(cadence.String))
+	}
+
+	return marketCreatedPayload{
+		MarketID:      uint64(fields["marketId"].(cadence.UInt64)),
+		Question:      string(fields["question"].(cadence.String)),
+		EndTime:       fmt.Sprintf("%d", int64(endTimeUFix64/100000000)),
+		Options:       options,
+		YieldProtocol: yieldProtocol,
+		Creator:       fields["creator"].(cadence.Address).String(),
+	}, nil
+}
+
+func (MarketCreatedHandler) Fields(payload any) map[string]any {
+	p, ok := payload.(marketCreatedPayload)
+	if !ok {
+		return nil
+	}
+
+	return map[string]any{
+		"marketId": p.MarketID,
+		"user":     p.Creator,
+		"protocol": p.YieldProtocol,
+	}
+}
+
+func (MarketCreatedHandler) Store(db *gorm.DB, decoded DecodedEvent) error {
+	payload, ok := decoded.Payload.(marketCreatedPayload)
+	if !ok {
+		return fmt.Errorf("unexpected payload type %T for MarketCreated", decoded.Payload)
+	}
+
+	return db.Create(&config.FlowMarketCreated{
+		MarketID:       payload.MarketID,
+		Question:       payload.Question,
+		EndTime:        payload.EndTime,
+		Protocols:      payload.Options,
+		Options:        payload.Options,
+		YieldProtocol:  payload.YieldProtocol,
+		Creator:        payload.Creator,
+		BlockHeight:    decoded.BlockHeight,
+		BlockTimestamp: decoded.BlockTimestamp,
+		BlockID:        decoded.BlockID,
+		Finalized:      decoded.Finalized,
+		TransactionID:  decoded.TransactionID,
+		EventIndex:     decoded.EventIndex,
+	}).Error
+}
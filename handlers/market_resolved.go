@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/onflow/cadence"
+	"gorm.io/gorm"
+
+	"github.com/evaafi/go-indexer/config"
+)
+
+type marketResolvedPayload struct {
+	MarketID      uint64
+	WinningOption string
+	FinalAPYs     map[string]interface{}
+	ResolvedAt    string
+}
+
+// MarketResolvedHandler decodes and stores TrixyEvents.MarketResolved.
+type MarketResolvedHandler struct{}
+
+func (MarketResolvedHandler) EventType() string { return "MarketResolved" }
+
+func (MarketResolvedHandler) Decode(event cadence.Event) (any, error) {
+	fields := cadence.FieldsMappedByName(event)
+
+	apysDict, ok := fields["finalAPYs"].(cadence.Dictionary)
+	if !ok {
+		return nil, fmt.Errorf("finalAPYs field has unexpected type %T", fields["finalAPYs"])
+	}
+
+	finalAPYs := make(map[string]interface{})
+
+	for _, pair := range apysDict.Pairs {
+		key := string(pair.Key.(cadence.String))
+		value := pair.Value.(cadence.UFix64).String()
+		finalAPYs[key] = value
+	}
+
+	return marketResolvedPayload{
+		MarketID:      uint64(fields["marketId"].(cadence.UInt64)),
+		WinningOption: string(fields["winningOption"].(cadence.String)),
+		FinalAPYs:     finalAPYs,
+		ResolvedAt:    fields["resolvedAt"].(cadence.UFix64).String(),
+	}, nil
+}
+
+func (MarketResolvedHandler) Fields(payload any) map[string]any {
+	p, ok := payload.(marketResolvedPayload)
+	if !ok {
+		return nil
+	}
+
+	return map[string]any{
+		"marketId": p.MarketID,
+	}
+}
+
+func (MarketResolvedHandler) Store(db *gorm.DB, decoded DecodedEvent) error {
+	payload, ok := decoded.Payload.(marketResolvedPayload)
+	if !ok {
+		return fmt.Errorf("unexpected payload type %T for MarketResolved", decoded.Payload)
+	}
+
+	return db.Create(&config.FlowMarketResolved{
+		MarketID:       payload.MarketID,
+		WinningOption:  payload.WinningOption,
+		FinalAPYs:      payload.FinalAPYs,
+		ResolvedAt:     payload.ResolvedAt,
+		BlockHeight:    decoded.BlockHeight,
+		BlockTimestamp: decoded.BlockTimestamp,
+		BlockID:        decoded.BlockID,
+		Finalized:      decoded.Finalized,
+		TransactionID:  decoded.TransactionID,
+		EventIndex:     decoded.EventIndex,
+	}).Error
+}
@@ -0,0 +1,125 @@
+// Package handlers breaks the indexer's per-event decode/store logic out
+// of one hard-coded switch into a registry of small, independently
+// testable EventHandlers, one per Trixy event.
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/onflow/cadence"
+	"gorm.io/gorm"
+
+	"github.com/evaafi/go-indexer/config"
+)
+
+// DecodedEvent carries a handler's decoded payload alongside the block and
+// transaction metadata every Trixy event row stores. Callers of
+// HandlerRegistry.Handle fill in everything but Payload; Handle sets
+// Payload from Decode's result before calling Store.
+type DecodedEvent struct {
+	Payload        any
+	BlockHeight    uint64
+	BlockTimestamp int64
+	BlockID        string
+	Finalized      bool
+	TransactionID  string
+	EventIndex     uint32
+}
+
+// EventHandler decodes one Trixy event type and stores it. EventType
+// returns the event's name as it appears after "TrixyEvents." in its
+// Cadence type ID (e.g. "MarketCreated"); the registry combines that with
+// each configured contract's address to build the full type string Flow
+// expects when querying or subscribing for events.
+type EventHandler interface {
+	EventType() string
+	Decode(event cadence.Event) (any, error)
+	Store(db *gorm.DB, decoded DecodedEvent) error
+
+	// Fields extracts the subset of a decoded payload that the server
+	// package's log filters can match against (marketId, user, protocol,
+	// ...). payload is always the value this handler's own Decode
+	// returned.
+	Fields(payload any) map[string]any
+}
+
+// Publisher is notified of every event a HandlerRegistry successfully
+// stores, decoupled from storage so the server package's pub/sub can feed
+// trixy_subscribe without HandlerRegistry importing it.
+type Publisher interface {
+	Publish(eventType, name string, fields map[string]any, meta DecodedEvent)
+}
+
+// HandlerRegistry maps Cadence event type strings to the handler that
+// decodes and stores them, built from every contract configured for this
+// network so indexing an additional contract is a config change rather
+// than a code change.
+type HandlerRegistry struct {
+	handlers   map[string]EventHandler
+	eventTypes map[string]string // "<contract>:<EventType>" -> Cadence type string
+	publisher  Publisher
+}
+
+// NewHandlerRegistry builds a registry covering every contract in
+// contracts crossed with every handler passed in.
+func NewHandlerRegistry(contracts []config.Contract, eventHandlers ...EventHandler) *HandlerRegistry {
+	r := &HandlerRegistry{
+		handlers:   make(map[string]EventHandler, len(contracts)*len(eventHandlers)),
+		eventTypes: make(map[string]string, len(contracts)*len(eventHandlers)),
+	}
+
+	for _, contract := range contracts {
+		address := strings.TrimPrefix(contract.Address, "0x")
+
+		for _, h := range eventHandlers {
+			cadenceType := fmt.Sprintf("A.%s.TrixyEvents.%s", address, h.EventType())
+
+			r.handlers[cadenceType] = h
+			r.eventTypes[fmt.Sprintf("%s:%s", contract.Name, h.EventType())] = cadenceType
+		}
+	}
+
+	return r
+}
+
+// EventTypes returns every Cadence type string this registry can handle,
+// labeled with a friendly "<contract>:<EventType>" name, suitable for an
+// Ingestor's EventTypes map.
+func (r *HandlerRegistry) EventTypes() map[string]string {
+	return r.eventTypes
+}
+
+// SetPublisher registers p to receive every event this registry stores.
+// It's optional: a registry with no publisher set behaves exactly as
+// before.
+func (r *HandlerRegistry) SetPublisher(p Publisher) {
+	r.publisher = p
+}
+
+// Handle decodes and stores event using whichever handler is registered
+// for eventType (Flow's Cadence type string), filling in meta.Payload
+// before calling Store. It returns an error if no handler is registered.
+func (r *HandlerRegistry) Handle(db *gorm.DB, eventType string, event cadence.Event, meta DecodedEvent) error {
+	h, ok := r.handlers[eventType]
+	if !ok {
+		return fmt.Errorf("no handler registered for event type %s", eventType)
+	}
+
+	payload, err := h.Decode(event)
+	if err != nil {
+		return fmt.Errorf("decoding %s: %w", eventType, err)
+	}
+
+	meta.Payload = payload
+
+	if err := h.Store(db, meta); err != nil {
+		return err
+	}
+
+	if r.publisher != nil {
+		r.publisher.Publish(eventType, h.EventType(), h.Fields(payload), meta)
+	}
+
+	return nil
+}
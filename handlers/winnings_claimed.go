@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/onflow/cadence"
+	"gorm.io/gorm"
+
+	"github.com/evaafi/go-indexer/config"
+)
+
+type winningsClaimedPayload struct {
+	MarketID uint64
+	User     string
+	Payout   string
+}
+
+// WinningsClaimedHandler decodes and stores TrixyEvents.WinningsClaimed.
+type WinningsClaimedHandler struct{}
+
+func (WinningsClaimedHandler) EventType() string { return "WinningsClaimed" }
+
+func (WinningsClaimedHandler) Decode(event cadence.Event) (any, error) {
+	fields := cadence.FieldsMappedByName(event)
+
+	return winningsClaimedPayload{
+		MarketID: uint64(fields["marketId"].(cadence.UInt64)),
+		User:     fields["user"].(cadence.Address).String(),
+		Payout:   fields["payout"].(cadence.UFix64).String(),
+	}, nil
+}
+
+func (WinningsClaimedHandler) Fields(payload any) map[string]any {
+	p, ok := payload.(winningsClaimedPayload)
+	if !ok {
+		return nil
+	}
+
+	return map[string]any{
+		"marketId": p.MarketID,
+		"user":     p.User,
+	}
+}
+
+func (WinningsClaimedHandler) Store(db *gorm.DB, decoded DecodedEvent) error {
+	payload, ok := decoded.Payload.(winningsClaimedPayload)
+	if !ok {
+		return fmt.Errorf("unexpected payload type %T for WinningsClaimed", decoded.Payload)
+	}
+
+	return db.Create(&config.FlowWinningsClaimed{
+		MarketID:       payload.MarketID,
+		User:           payload.User,
+		Payout:         payload.Payout,
+		BlockHeight:    decoded.BlockHeight,
+		BlockTimestamp: decoded.BlockTimestamp,
+		BlockID:        decoded.BlockID,
+		Finalized:      decoded.Finalized,
+		TransactionID:  decoded.TransactionID,
+		EventIndex:     decoded.EventIndex,
+	}).Error
+}
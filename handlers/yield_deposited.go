@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/onflow/cadence"
+	"gorm.io/gorm"
+
+	"github.com/evaafi/go-indexer/config"
+)
+
+type yieldDepositedPayload struct {
+	UserAddress  string
+	ProtocolName string
+	Amount       string
+	PositionID   string
+}
+
+// YieldDepositedHandler decodes and stores TrixyEvents.YieldDeposited.
+type YieldDepositedHandler struct{}
+
+func (YieldDepositedHandler) EventType() string { return "YieldDeposited" }
+
+func (YieldDepositedHandler) Decode(event cadence.Event) (any, error) {
+	fields := cadence.FieldsMappedByName(event)
+
+	// Extract fields with fallbacks for different field names
+	userAddress := ""
+	if userField := fields["user"]; userField != nil {
+		userAddress = userField.(cadence.Address).String()
+	} else if userAddrField := fields["userAddress"]; userAddrField != nil {
+		userAddress = userAddrField.(cadence.Address).String()
+	}
+
+	protocolName := ""
+	if protocolField := fields["protocol"]; protocolField != nil {
+		protocolName = string(protocolField.(cadence.String))
+	} else if protoNameField := fields["protocolName"]; protoNameField != nil {
+		protocolName = string(protoNameField.(cadence.String))
+	}
+
+	positionID := ""
+	if posIDField := fields["positionId"]; posIDField != nil {
+		positionID = string(posIDField.(cadence.String))
+	} else if marketIDField := fields["marketId"]; marketIDField != nil {
+		positionID = fmt.Sprintf("%d", uint64(marketIDField.(cadence.UInt64)))
+	}
+
+	return yieldDepositedPayload{
+		UserAddress:  userAddress,
+		ProtocolName: protocolName,
+		Amount:       fields["amount"].(cadence.UFix64).String(),
+		PositionID:   positionID,
+	}, nil
+}
+
+func (YieldDepositedHandler) Fields(payload any) map[string]any {
+	p, ok := payload.(yieldDepositedPayload)
+	if !ok {
+		return nil
+	}
+
+	return map[string]any{
+		"user":     p.UserAddress,
+		"protocol": p.ProtocolName,
+	}
+}
+
+func (YieldDepositedHandler) Store(db *gorm.DB, decoded DecodedEvent) error {
+	payload, ok := decoded.Payload.(yieldDepositedPayload)
+	if !ok {
+		return fmt.Errorf("unexpected payload type %T for YieldDeposited", decoded.Payload)
+	}
+
+	return db.Create(&config.FlowYieldDeposited{
+		UserAddress:    payload.UserAddress,
+		ProtocolName:   payload.ProtocolName,
+		Amount:         payload.Amount,
+		PositionID:     payload.PositionID,
+		BlockHeight:    decoded.BlockHeight,
+		BlockTimestamp: decoded.BlockTimestamp,
+		BlockID:        decoded.BlockID,
+		Finalized:      decoded.Finalized,
+		TransactionID:  decoded.TransactionID,
+		EventIndex:     decoded.EventIndex,
+	}).Error
+}
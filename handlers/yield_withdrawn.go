@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/onflow/cadence"
+	"gorm.io/gorm"
+
+	"github.com/evaafi/go-indexer/config"
+)
+
+type yieldWithdrawnPayload struct {
+	MarketID    uint64
+	Protocol    string
+	Amount      string
+	YieldEarned string
+}
+
+// YieldWithdrawnHandler decodes and stores TrixyEvents.YieldWithdrawn.
+type YieldWithdrawnHandler struct{}
+
+func (YieldWithdrawnHandler) EventType() string { return "YieldWithdrawn" }
+
+func (YieldWithdrawnHandler) Decode(event cadence.Event) (any, error) {
+	fields := cadence.FieldsMappedByName(event)
+
+	return yieldWithdrawnPayload{
+		MarketID:    uint64(fields["marketId"].(cadence.UInt64)),
+		Protocol:    string(fields["protocol"].(cadence.String)),
+		Amount:      fields["amount"].(cadence.UFix64).String(),
+		YieldEarned: fields["yieldEarned"].(cadence.UFix64).String(),
+	}, nil
+}
+
+func (YieldWithdrawnHandler) Fields(payload any) map[string]any {
+	p, ok := payload.(yieldWithdrawnPayload)
+	if !ok {
+		return nil
+	}
+
+	return map[string]any{
+		"marketId": p.MarketID,
+		"protocol": p.Protocol,
+	}
+}
+
+func (YieldWithdrawnHandler) Store(db *gorm.DB, decoded DecodedEvent) error {
+	payload, ok := decoded.Payload.(yieldWithdrawnPayload)
+	if !ok {
+		return fmt.Errorf("unexpected payload type %T for YieldWithdrawn", decoded.Payload)
+	}
+
+	return db.Create(&config.FlowYieldWithdrawn{
+		MarketID:       payload.MarketID,
+		Protocol:       payload.Protocol,
+		Amount:         payload.Amount,
+		YieldEarned:    payload.YieldEarned,
+		BlockHeight:    decoded.BlockHeight,
+		BlockTimestamp: decoded.BlockTimestamp,
+		BlockID:        decoded.BlockID,
+		Finalized:      decoded.Finalized,
+		TransactionID:  decoded.TransactionID,
+		EventIndex:     decoded.EventIndex,
+	}).Error
+}
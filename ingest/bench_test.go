@@ -0,0 +1,28 @@
+package ingest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/access/grpc"
+)
+
+func BenchmarkSplitRange(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		splitRange(0, 199, 8)
+	}
+}
+
+func BenchmarkBlockCacheGetHit(b *testing.B) {
+	cache := NewBlockCache(128)
+	cache.insert(1, &flow.Block{})
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := cache.Get(context.Background(), (*grpc.Client)(nil), 1); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
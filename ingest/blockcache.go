@@ -0,0 +1,94 @@
+package ingest
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/access/grpc"
+)
+
+// BlockCache is a small LRU cache in front of GetBlockByHeight. A block is
+// looked up once per batch for its ParentID and again by the Sink for its
+// timestamp, and bursty blocks can carry many events each wanting the same
+// block — caching turns all of that into a single Access API round-trip.
+type BlockCache struct {
+	capacity int
+
+	mu      sync.Mutex
+	entries map[uint64]*list.Element
+	order   *list.List // front = most recently used
+}
+
+type blockCacheEntry struct {
+	height uint64
+	block  *flow.Block
+}
+
+// NewBlockCache creates a BlockCache holding up to capacity blocks.
+func NewBlockCache(capacity int) *BlockCache {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	return &BlockCache{
+		capacity: capacity,
+		entries:  make(map[uint64]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Get returns the block at height, fetching it via client on a cache miss.
+func (c *BlockCache) Get(ctx context.Context, client *grpc.Client, height uint64) (*flow.Block, error) {
+	if block, ok := c.lookup(height); ok {
+		return block, nil
+	}
+
+	block, err := client.GetBlockByHeight(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+
+	c.insert(height, block)
+
+	return block, nil
+}
+
+func (c *BlockCache) lookup(height uint64) (*flow.Block, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[height]
+	if !ok {
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+
+	return elem.Value.(*blockCacheEntry).block, true
+}
+
+func (c *BlockCache) insert(height uint64, block *flow.Block) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[height]; ok {
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[height] = c.order.PushFront(&blockCacheEntry{height: height, block: block})
+
+	if c.order.Len() <= c.capacity {
+		return
+	}
+
+	oldest := c.order.Back()
+	if oldest == nil {
+		return
+	}
+
+	c.order.Remove(oldest)
+	delete(c.entries, oldest.Value.(*blockCacheEntry).height)
+}
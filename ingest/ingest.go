@@ -0,0 +1,187 @@
+// Package ingest contains the strategies the indexer uses to pull Flow
+// events into storage: a polling strategy that batches
+// GetEventsForHeightRange calls, and a streaming strategy built on the
+// Access API's block/event subscriptions. Callers pick one per network via
+// the Ingestor interface so neither strategy needs to know about the other.
+package ingest
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/access/grpc"
+)
+
+// BlockEvent pairs a decoded event with the human-readable name it was
+// fetched under: its key in the Ingestor's EventTypes map (e.g.
+// "<contract>:MarketCreated", as built by HandlerRegistry.EventTypes).
+type BlockEvent struct {
+	Name  string
+	Event flow.Event
+}
+
+// EventBatch groups every event observed in a single block so a Sink can
+// persist them together and checkpoint after the block, rather than after
+// an entire multi-block batch. ParentBlockID lets a Sink detect reorgs by
+// comparing it against whatever it last saw at BlockHeight-1.
+type EventBatch struct {
+	BlockHeight   uint64
+	BlockID       flow.Identifier
+	ParentBlockID flow.Identifier
+	Events        []BlockEvent
+}
+
+// Sink persists a batch of events. Implementations are expected to advance
+// their durable checkpoint (FlowSyncState.LastBlockHeight) only after a
+// batch has been committed, preserving today's at-least-once semantics.
+type Sink interface {
+	HandleBatch(ctx context.Context, batch EventBatch) error
+}
+
+// Ingestor drives events from the chain into a Sink until ctx is cancelled.
+type Ingestor interface {
+	Run(ctx context.Context, sink Sink) error
+}
+
+type blockRange struct {
+	start, end uint64
+}
+
+// splitRange divides [start, end] into up to workers contiguous, ascending
+// sub-ranges of roughly equal size.
+func splitRange(start, end uint64, workers int) []blockRange {
+	if workers < 1 {
+		workers = 1
+	}
+
+	total := end - start + 1
+	if uint64(workers) > total {
+		workers = int(total)
+	}
+
+	chunk := total / uint64(workers)
+	remainder := total % uint64(workers)
+
+	ranges := make([]blockRange, 0, workers)
+	cursor := start
+
+	for i := 0; i < workers; i++ {
+		size := chunk
+		if uint64(i) < remainder {
+			size++
+		}
+
+		ranges = append(ranges, blockRange{start: cursor, end: cursor + size - 1})
+		cursor += size
+	}
+
+	return ranges
+}
+
+// fetchRangeParallel splits [startBlock, endBlock] across up to workers
+// goroutines, each fetching its sub-range with fetchRange, and fans the
+// results back in over a channel before reassembling them in ascending
+// block order. It's shared by PollingIngestor and the historical-backfill
+// leg of StreamingIngestor so both strategies see identical batch
+// semantics.
+func fetchRangeParallel(ctx context.Context, client *grpc.Client, cache *BlockCache, eventTypes map[string]string, startBlock, endBlock uint64, workers int) ([]EventBatch, error) {
+	ranges := splitRange(startBlock, endBlock, workers)
+
+	type rangeResult struct {
+		index   int
+		batches []EventBatch
+		err     error
+	}
+
+	resultsCh := make(chan rangeResult, len(ranges))
+
+	for i, r := range ranges {
+		go func(i int, r blockRange) {
+			batches, err := fetchRange(ctx, client, cache, eventTypes, r.start, r.end)
+			resultsCh <- rangeResult{index: i, batches: batches, err: err}
+		}(i, r)
+	}
+
+	ordered := make([][]EventBatch, len(ranges))
+
+	for range ranges {
+		res := <-resultsCh
+		if res.err != nil {
+			return nil, res.err
+		}
+
+		ordered[res.index] = res.batches
+	}
+
+	var out []EventBatch
+	for _, batches := range ordered {
+		out = append(out, batches...)
+	}
+
+	return out, nil
+}
+
+// fetchRange queries every configured event type over [startBlock, endBlock]
+// concurrently and regroups the results per block, in ascending block
+// order. A batch is emitted for every height in the range, even ones with
+// no matching events, so the caller's checkpoint (and reorg tracking)
+// advances with every block walked rather than only ones that produced a
+// Trixy event.
+func fetchRange(ctx context.Context, client *grpc.Client, cache *BlockCache, eventTypes map[string]string, startBlock, endBlock uint64) ([]EventBatch, error) {
+	type typeResult struct {
+		name    string
+		results []flow.BlockEvents
+		err     error
+	}
+
+	resultsCh := make(chan typeResult, len(eventTypes))
+
+	for name, eventType := range eventTypes {
+		go func(name, eventType string) {
+			results, err := client.GetEventsForHeightRange(ctx, eventType, startBlock, endBlock)
+			resultsCh <- typeResult{name: name, results: results, err: err}
+		}(name, eventType)
+	}
+
+	byHeight := map[uint64]*EventBatch{}
+
+	for range eventTypes {
+		res := <-resultsCh
+		if res.err != nil {
+			return nil, fmt.Errorf("querying %s: %w", res.name, res.err)
+		}
+
+		for _, blockEvents := range res.results {
+			batch, ok := byHeight[blockEvents.Height]
+			if !ok {
+				batch = &EventBatch{BlockHeight: blockEvents.Height, BlockID: blockEvents.BlockID}
+				byHeight[blockEvents.Height] = batch
+			}
+
+			for _, event := range blockEvents.Events {
+				batch.Events = append(batch.Events, BlockEvent{Name: res.name, Event: event})
+			}
+		}
+	}
+
+	ordered := make([]EventBatch, 0, endBlock-startBlock+1)
+
+	for height := startBlock; height <= endBlock; height++ {
+		block, err := cache.Get(ctx, client, height)
+		if err != nil {
+			return nil, fmt.Errorf("fetching block %d: %w", height, err)
+		}
+
+		batch, ok := byHeight[height]
+		if !ok {
+			batch = &EventBatch{BlockHeight: height, BlockID: block.ID}
+		}
+
+		batch.ParentBlockID = block.ParentID
+
+		ordered = append(ordered, *batch)
+	}
+
+	return ordered, nil
+}
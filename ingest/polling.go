@@ -0,0 +1,97 @@
+package ingest
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/onflow/flow-go-sdk/access/grpc"
+)
+
+// PollingIngestor reproduces the indexer's original behavior: repeatedly
+// diff the synced height against the chain tip and walk forward in fixed
+// size batches via GetEventsForHeightRange. It's the right choice for
+// networks/access nodes that don't support event subscriptions.
+type PollingIngestor struct {
+	Client       *grpc.Client
+	EventTypes   map[string]string
+	StartHeight  uint64
+	BatchSize    uint64
+	PollInterval time.Duration
+
+	// FinalityConfirmations, when non-zero, indexes FinalityConfirmations
+	// blocks behind the chain tip instead of waiting for Flow's own sealed
+	// block, trading durability for lower latency.
+	FinalityConfirmations uint64
+
+	// Cache is shared with the Sink so a block fetched to resolve a batch's
+	// ParentBlockID isn't fetched again to resolve its timestamp.
+	Cache *BlockCache
+
+	// Workers bounds how many sub-ranges of a batch window are fetched
+	// concurrently. Defaults to 1 (sequential) if unset.
+	Workers int
+}
+
+// Run blocks until ctx is cancelled, polling for new blocks every
+// PollInterval and delivering one EventBatch per block to sink. It returns
+// as soon as sink.HandleBatch reports an error so the caller can react
+// (e.g. restart after a reorg rewind) instead of silently skipping batches.
+func (p *PollingIngestor) Run(ctx context.Context, sink Sink) error {
+	current := p.StartHeight
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		latest, err := p.Client.GetLatestBlock(ctx, p.FinalityConfirmations == 0)
+		if err != nil {
+			log.Printf("Error getting latest block: %v", err)
+			time.Sleep(p.PollInterval)
+
+			continue
+		}
+
+		target := targetHeight(latest.Height, p.FinalityConfirmations)
+
+		for current < target {
+			endBlock := current + p.BatchSize
+			if endBlock > target {
+				endBlock = target
+			}
+
+			batches, err := fetchRangeParallel(ctx, p.Client, p.Cache, p.EventTypes, current, endBlock, p.Workers)
+			if err != nil {
+				log.Printf("Error fetching blocks %d-%d: %v", current, endBlock, err)
+				break
+			}
+
+			for _, batch := range batches {
+				if err := sink.HandleBatch(ctx, batch); err != nil {
+					return err
+				}
+			}
+
+			current = endBlock + 1
+		}
+
+		time.Sleep(p.PollInterval)
+	}
+}
+
+// targetHeight applies FinalityConfirmations to the chain tip, clamping at
+// zero so a fresh network doesn't underflow.
+func targetHeight(tip, confirmations uint64) uint64 {
+	if confirmations == 0 {
+		return tip
+	}
+
+	if tip < confirmations {
+		return 0
+	}
+
+	return tip - confirmations
+}
@@ -0,0 +1,123 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/onflow/flow-go-sdk/access/grpc"
+)
+
+// StreamingIngestor backfills historical blocks with the same
+// GetEventsForHeightRange batching PollingIngestor uses, then switches to
+// the Access API's SubscribeEventsByBlockHeight for near-real-time
+// delivery. The subscription only yields blocks once Flow has sealed them,
+// so committing a batch as soon as it's handled preserves the durability
+// guarantees the polling path gets from waiting for its batch window.
+type StreamingIngestor struct {
+	Client      *grpc.Client
+	EventTypes  map[string]string
+	StartHeight uint64
+	BatchSize   uint64 // historical backfill leg only
+
+	// FinalityConfirmations, when non-zero, backfills only up to
+	// FinalityConfirmations blocks behind the chain tip before switching to
+	// the live subscription.
+	FinalityConfirmations uint64
+
+	// Cache is shared with the Sink so a block fetched during backfill or
+	// live streaming isn't fetched again downstream.
+	Cache *BlockCache
+
+	// Workers bounds how many sub-ranges of the backfill window are fetched
+	// concurrently. Defaults to 1 (sequential) if unset.
+	Workers int
+}
+
+// Run backfills [StartHeight, target], where target is the chain tip less
+// FinalityConfirmations, and then streams forward from the next height
+// until ctx is cancelled or the subscription ends.
+func (s *StreamingIngestor) Run(ctx context.Context, sink Sink) error {
+	latest, err := s.Client.GetLatestBlock(ctx, s.FinalityConfirmations == 0)
+	if err != nil {
+		return fmt.Errorf("getting latest block for backfill: %w", err)
+	}
+
+	target := targetHeight(latest.Height, s.FinalityConfirmations)
+	current := s.StartHeight
+
+	for current < target {
+		endBlock := current + s.BatchSize
+		if endBlock > target {
+			endBlock = target
+		}
+
+		batches, err := fetchRangeParallel(ctx, s.Client, s.Cache, s.EventTypes, current, endBlock, s.Workers)
+		if err != nil {
+			return fmt.Errorf("backfilling blocks %d-%d: %w", current, endBlock, err)
+		}
+
+		for _, batch := range batches {
+			if err := sink.HandleBatch(ctx, batch); err != nil {
+				return fmt.Errorf("handling backfilled batch at block %d: %w", batch.BlockHeight, err)
+			}
+		}
+
+		current = endBlock + 1
+	}
+
+	return s.stream(ctx, sink, current)
+}
+
+func (s *StreamingIngestor) stream(ctx context.Context, sink Sink, fromHeight uint64) error {
+	types := make([]string, 0, len(s.EventTypes))
+	typeNames := make(map[string]string, len(s.EventTypes))
+
+	for name, eventType := range s.EventTypes {
+		types = append(types, eventType)
+		typeNames[eventType] = name
+	}
+
+	eventsCh, errCh, err := s.Client.SubscribeEventsByBlockHeight(ctx, fromHeight, grpc.EventFilter{EventTypes: types})
+	if err != nil {
+		return fmt.Errorf("subscribing to events from block %d: %w", fromHeight, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err, ok := <-errCh:
+			if !ok {
+				return nil
+			}
+
+			return fmt.Errorf("event subscription error: %w", err)
+		case blockEvents, ok := <-eventsCh:
+			if !ok {
+				return nil
+			}
+
+			batch := EventBatch{BlockHeight: blockEvents.Height, BlockID: blockEvents.BlockID}
+
+			if block, err := s.Cache.Get(ctx, s.Client, blockEvents.Height); err != nil {
+				log.Printf("Error fetching parent block ID for %d: %v", blockEvents.Height, err)
+			} else {
+				batch.ParentBlockID = block.ParentID
+			}
+
+			for _, event := range blockEvents.Events {
+				name, ok := typeNames[event.Type]
+				if !ok {
+					continue
+				}
+
+				batch.Events = append(batch.Events, BlockEvent{Name: name, Event: event})
+			}
+
+			if err := sink.HandleBatch(ctx, batch); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -2,22 +2,35 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
 	"time"
 
-	"github.com/onflow/cadence"
-	"github.com/onflow/flow-go-sdk"
 	"github.com/onflow/flow-go-sdk/access/grpc"
 	"gorm.io/gorm"
 
 	"github.com/evaafi/go-indexer/config"
+	"github.com/evaafi/go-indexer/handlers"
+	"github.com/evaafi/go-indexer/ingest"
+	"github.com/evaafi/go-indexer/reorg"
+	"github.com/evaafi/go-indexer/server"
 )
 
 const (
 	batchSize    = 200
 	pollInterval = 2 * time.Second
+
+	// retainedHeaders bounds how many recent block headers the reorg guard
+	// keeps around to walk back through when looking for a fork point.
+	retainedHeaders = 450
+
+	// blockCacheSize bounds how many recent blocks the shared BlockCache
+	// retains; comfortably larger than a batch window so every block
+	// fetched while assembling a batch is still warm when the Sink looks
+	// it up again for its timestamp.
+	blockCacheSize = 2 * batchSize
 )
 
 func main() {
@@ -57,303 +70,223 @@ func main() {
 		log.Fatal("No contracts configured")
 	}
 
-	contract := config.Contracts[0]
-	contractAddress := strings.TrimPrefix(contract.Address, "0x")
-
-	fmt.Printf("Contract: %s at %s\n", contract.Name, contract.Address)
+	for _, contract := range config.Contracts {
+		fmt.Printf("Contract: %s at %s\n", contract.Name, contract.Address)
+	}
 	fmt.Printf("Network: %s\n", cfg.Network)
 	fmt.Printf("RPC: %s\n\n", cfg.RPCEndpoint)
 
-	var syncState config.FlowSyncState
+	// Every configured contract is walked over the same block range by the
+	// same Ingestor, so each gets its own FlowSyncState row (for per-contract
+	// visibility and its own StartBlock) but all of them advance together;
+	// startHeight below resumes from whichever contract is furthest behind
+	// so a newly added contract still gets backfilled from its StartBlock.
+	syncStates := make([]*config.FlowSyncState, 0, len(config.Contracts))
+
+	for _, contract := range config.Contracts {
+		var syncState config.FlowSyncState
+
+		result := db.Where("contract_address = ?", contract.Address).First(&syncState)
+		if result.Error != nil {
+			syncState = config.FlowSyncState{
+				ContractAddress: contract.Address,
+				ContractName:    contract.Name,
+				Network:         cfg.Network,
+				LastBlockHeight: uint64(contract.StartBlock),
+			}
+			if err := db.Create(&syncState).Error; err != nil {
+				log.Fatalf("Failed to create sync state: %v", err)
+			}
 
-	result := db.Where("contract_address = ?", contract.Address).First(&syncState)
-	if result.Error != nil {
-		syncState = config.FlowSyncState{
-			ContractAddress: contract.Address,
-			ContractName:    contract.Name,
-			Network:         cfg.Network,
-			LastBlockHeight: uint64(contract.StartBlock),
-		}
-		if err := db.Create(&syncState).Error; err != nil {
-			log.Fatalf("Failed to create sync state: %v", err)
+			fmt.Printf("Created new sync state for %s starting at block %d\n", contract.Name, contract.StartBlock)
+		} else {
+			fmt.Printf("Resuming %s from block %d\n", contract.Name, syncState.LastBlockHeight)
 		}
 
-		fmt.Printf("Created new sync state starting at block %d\n\n", contract.StartBlock)
-	} else {
-		fmt.Printf("Resuming from block %d\n\n", syncState.LastBlockHeight)
+		syncStates = append(syncStates, &syncState)
 	}
 
+	startHeight := minSyncHeight(syncStates)
+
+	fmt.Println()
+
 	latestBlock, err := flowClient.GetLatestBlock(ctx, true)
 	if err != nil {
 		log.Fatalf("Failed to get latest block: %v", err)
 	}
 
 	fmt.Printf("Latest network block: %d\n", latestBlock.Height)
-	fmt.Printf("Blocks to index: %d\n\n", latestBlock.Height-syncState.LastBlockHeight)
-
-	totalEvents := 0
+	fmt.Printf("Blocks to index: %d\n\n", latestBlock.Height-startHeight)
+
+	registry := handlers.NewHandlerRegistry(
+		config.Contracts,
+		handlers.MarketCreatedHandler{},
+		handlers.BetPlacedHandler{},
+		handlers.MarketResolvedHandler{},
+		handlers.WinningsClaimedHandler{},
+		handlers.YieldDepositedHandler{},
+		handlers.YieldWithdrawnHandler{},
+	)
+	eventTypes := registry.EventTypes()
+	blockCache := ingest.NewBlockCache(blockCacheSize)
+
+	hub := server.NewHub()
+	registry.SetPublisher(hub)
+
+	apiServer := server.NewServer(cfg.RPCServerAddr, db, hub)
+
+	go func() {
+		if err := apiServer.ListenAndServe(); err != nil {
+			log.Printf("API server stopped: %v", err)
+		}
+	}()
 
-	fmt.Println("🔄 Starting continuous indexing mode...")
+	sink := &dbSink{
+		db:         db,
+		flowClient: flowClient,
+		syncStates: syncStates,
+		registry:   registry,
+		guard:      &reorg.Guard{DB: db, Client: flowClient, RetainHeaders: retainedHeaders},
+		finalized:  cfg.FinalityConfirmations == 0,
+		blockCache: blockCache,
+	}
 
 	for {
-		latestBlock, err := flowClient.GetLatestBlock(ctx, true)
-		if err != nil {
-			log.Printf("Error getting latest block: %v", err)
-			time.Sleep(pollInterval)
-
-			continue
-		}
-
-		if err := db.Where("contract_address = ?", contract.Address).First(&syncState).Error; err != nil {
-			log.Printf("Error loading sync state: %v", err)
-			time.Sleep(pollInterval)
+		resumeHeight := minSyncHeight(syncStates)
+		ingestor := newIngestor(cfg, flowClient, eventTypes, resumeHeight, blockCache)
 
+		err := ingestor.Run(ctx, sink)
+		if errors.Is(err, reorg.ErrDetected) {
+			fmt.Printf("↺ %v; resuming from block %d\n\n", err, minSyncHeight(syncStates))
 			continue
 		}
 
-		currentBlock := syncState.LastBlockHeight
-
-		if currentBlock < latestBlock.Height {
-			fmt.Printf("📈 Latest block: %d, Current: %d, Gap: %d blocks\n", latestBlock.Height, currentBlock, latestBlock.Height-currentBlock)
-
-			for currentBlock < latestBlock.Height {
-				endBlock := currentBlock + batchSize
-				if endBlock > latestBlock.Height {
-					endBlock = latestBlock.Height
-				}
-
-				fmt.Printf("⏳ Indexing blocks %d to %d...\n", currentBlock, endBlock)
-
-				events := indexBatch(ctx, db, flowClient, contractAddress, currentBlock, endBlock)
-				totalEvents += events
-
-				if events > 0 {
-					fmt.Printf("✓ Indexed %d events\n\n", events)
-				} else {
-					fmt.Printf("  No events found\n\n")
-				}
-
-				syncState.LastBlockHeight = endBlock
-				if err := db.Save(&syncState).Error; err != nil {
-					log.Printf("Warning: Failed to update sync state: %v", err)
-				}
+		log.Fatalf("Ingestion stopped: %v", err)
+	}
+}
 
-				currentBlock = endBlock + 1
-			}
+// minSyncHeight returns the lowest LastBlockHeight across syncStates, the
+// height the shared Ingestor should resume from so no configured contract's
+// events are skipped.
+func minSyncHeight(syncStates []*config.FlowSyncState) uint64 {
+	min := syncStates[0].LastBlockHeight
 
-			fmt.Printf("✅ Caught up! Total events: %d\n", totalEvents)
-			showTableCounts(db)
-			fmt.Printf("\n⏰ Waiting %v for new blocks...\n\n", pollInterval)
-		} else {
-			fmt.Printf("⏰ Up to date at block %d. Checking again in %v...\n", currentBlock, pollInterval)
+	for _, s := range syncStates[1:] {
+		if s.LastBlockHeight < min {
+			min = s.LastBlockHeight
 		}
-
-		time.Sleep(pollInterval)
 	}
-}
 
-func indexBatch(ctx context.Context, db *gorm.DB, flowClient *grpc.Client, contractAddress string, startBlock, endBlock uint64) int {
-	total := 0
+	return min
+}
 
-	eventTypes := map[string]string{
-		"MarketCreated":   fmt.Sprintf("A.%s.TrixyEvents.MarketCreated", contractAddress),
-		"BetPlaced":       fmt.Sprintf("A.%s.TrixyEvents.BetPlaced", contractAddress),
-		"MarketResolved":  fmt.Sprintf("A.%s.TrixyEvents.MarketResolved", contractAddress),
-		"WinningsClaimed": fmt.Sprintf("A.%s.TrixyEvents.WinningsClaimed", contractAddress),
-		"YieldDeposited":  fmt.Sprintf("A.%s.TrixyEvents.YieldDeposited", contractAddress),
-		"YieldWithdrawn":  fmt.Sprintf("A.%s.TrixyEvents.YieldWithdrawn", contractAddress),
+// newIngestor picks the ingestion strategy for cfg.Network: StreamingIngestor
+// where the operator has opted in, PollingIngestor otherwise. Both share
+// cache so a block fetched by one code path stays warm for the other.
+func newIngestor(cfg *config.Config, flowClient *grpc.Client, eventTypes map[string]string, startHeight uint64, cache *ingest.BlockCache) ingest.Ingestor {
+	workers := cfg.Workers
+	if workers < 1 {
+		workers = 1
 	}
 
-	for name, eventType := range eventTypes {
-		events, err := flowClient.GetEventsForHeightRange(ctx, eventType, startBlock, endBlock)
-		if err != nil {
-			log.Printf("Error querying %s: %v", name, err)
-			continue
+	if cfg.StreamingEnabled {
+		fmt.Println("🔄 Starting streaming ingestion mode...")
+
+		return &ingest.StreamingIngestor{
+			Client:                flowClient,
+			EventTypes:            eventTypes,
+			StartHeight:           startHeight,
+			BatchSize:             batchSize,
+			FinalityConfirmations: cfg.FinalityConfirmations,
+			Cache:                 cache,
+			Workers:               workers,
 		}
+	}
 
-		for _, blockEvents := range events {
-			for _, event := range blockEvents.Events {
-				if err := storeEvent(ctx, db, flowClient, event, blockEvents.Height, name); err != nil {
-					if !strings.Contains(err.Error(), "duplicate key") {
-						log.Printf("Error storing %s event: %v", name, err)
-					}
-
-					continue
-				}
+	fmt.Println("🔄 Starting continuous indexing mode...")
 
-				total++
-			}
-		}
+	return &ingest.PollingIngestor{
+		Client:                flowClient,
+		EventTypes:            eventTypes,
+		StartHeight:           startHeight,
+		BatchSize:             batchSize,
+		PollInterval:          pollInterval,
+		FinalityConfirmations: cfg.FinalityConfirmations,
+		Cache:                 cache,
+		Workers:               workers,
 	}
+}
 
-	return total
+// dbSink persists the event batches an Ingestor produces, storing each
+// decoded event and advancing every configured contract's FlowSyncState
+// only once its batch is committed, whichever strategy produced it. Before
+// persisting, it runs each batch past a reorg guard so a diverged chain
+// gets rewound instead of leaving stale rows behind.
+type dbSink struct {
+	db          *gorm.DB
+	flowClient  *grpc.Client
+	syncStates  []*config.FlowSyncState
+	registry    *handlers.HandlerRegistry
+	guard       *reorg.Guard
+	finalized   bool
+	totalEvents int
+	blockCache  *ingest.BlockCache
 }
 
-func storeEvent(ctx context.Context, db *gorm.DB, flowClient *grpc.Client, event flow.Event, blockHeight uint64, eventName string) error {
-	block, err := flowClient.GetBlockByHeight(ctx, blockHeight)
+func (s *dbSink) HandleBatch(ctx context.Context, batch ingest.EventBatch) error {
+	forkHeight, err := s.guard.Observe(ctx, batch.BlockHeight, batch.BlockID.String(), batch.ParentBlockID.String(), s.finalized)
 	if err != nil {
-		return err
-	}
-
-	fields := cadence.FieldsMappedByName(event.Value)
-
-	switch eventName {
-	case "MarketCreated":
-		options := []string{}
-
-		optionsField := fields["options"]
-		if optionsField == nil {
-			optionsField = fields["protocols"]
-		}
-
-		if optionsField != nil {
-			for _, p := range optionsField.(cadence.Array).Values {
-				options = append(options, string(p.(cadence.String)))
+		if errors.Is(err, reorg.ErrDetected) {
+			if rewindErr := reorg.Rewind(s.db, s.syncStates, forkHeight); rewindErr != nil {
+				return fmt.Errorf("%w: rewinding after reorg: %v", reorg.ErrDetected, rewindErr)
 			}
 		}
 
-		endTimeUFix64 := fields["endTime"].(cadence.UFix64)
-		endTimeInt := int64(endTimeUFix64 / 100000000)
+		return err
+	}
 
-		yieldProtocol := ""
-		if yieldProtoField := fields["yieldProtocol"]; yieldProtoField != nil {
-			yieldProtocol = string(yieldProtoField.(cadence.String))
-		}
+	block, err := s.blockCache.Get(ctx, s.flowClient, batch.BlockHeight)
+	if err != nil {
+		return fmt.Errorf("fetching block %d: %w", batch.BlockHeight, err)
+	}
 
-		return db.Create(&config.FlowMarketCreated{
-			MarketID:       uint64(fields["marketId"].(cadence.UInt64)),
-			Question:       string(fields["question"].(cadence.String)),
-			EndTime:        fmt.Sprintf("%d", endTimeInt),
-			Protocols:      options,
-			Options:        options,
-			YieldProtocol:  yieldProtocol,
-			Creator:        fields["creator"].(cadence.Address).String(),
-			BlockHeight:    blockHeight,
-			BlockTimestamp: block.Timestamp.Unix(),
-			TransactionID:  event.TransactionID.String(),
-			EventIndex:     uint32(event.EventIndex),
-		}).Error
-
-	case "BetPlaced":
-		// Extract protocolIndex, default to 0 if not present
-		protocolIndex := uint32(0)
-		if protocolIndexField := fields["protocolIndex"]; protocolIndexField != nil {
-			protocolIndex = uint32(protocolIndexField.(cadence.UInt32))
-		}
+	meta := handlers.DecodedEvent{
+		BlockHeight:    batch.BlockHeight,
+		BlockTimestamp: block.Timestamp.Unix(),
+		BlockID:        batch.BlockID.String(),
+		Finalized:      s.finalized,
+	}
 
-		return db.Create(&config.FlowBetPlaced{
-			MarketID:       uint64(fields["marketId"].(cadence.UInt64)),
-			User:           fields["user"].(cadence.Address).String(),
-			SelectedOption: string(fields["selectedOption"].(cadence.String)),
-			ProtocolIndex:  protocolIndex,
-			Amount:         fields["amount"].(cadence.UFix64).String(),
-			BlockHeight:    blockHeight,
-			BlockTimestamp: block.Timestamp.Unix(),
-			TransactionID:  event.TransactionID.String(),
-			EventIndex:     uint32(event.EventIndex),
-		}).Error
-
-	case "MarketResolved":
-		apysDict := fields["finalAPYs"].(cadence.Dictionary)
-		finalAPYs := make(map[string]interface{})
-
-		for _, pair := range apysDict.Pairs {
-			key := string(pair.Key.(cadence.String))
-			value := pair.Value.(cadence.UFix64).String()
-			finalAPYs[key] = value
-		}
+	stored := 0
 
-		return db.Create(&config.FlowMarketResolved{
-			MarketID:       uint64(fields["marketId"].(cadence.UInt64)),
-			WinningOption:  string(fields["winningOption"].(cadence.String)),
-			FinalAPYs:      finalAPYs,
-			ResolvedAt:     fields["resolvedAt"].(cadence.UFix64).String(),
-			BlockHeight:    blockHeight,
-			BlockTimestamp: block.Timestamp.Unix(),
-			TransactionID:  event.TransactionID.String(),
-			EventIndex:     uint32(event.EventIndex),
-		}).Error
-
-	case "WinningsClaimed":
-		return db.Create(&config.FlowWinningsClaimed{
-			MarketID:       uint64(fields["marketId"].(cadence.UInt64)),
-			User:           fields["user"].(cadence.Address).String(),
-			Payout:         fields["payout"].(cadence.UFix64).String(),
-			BlockHeight:    blockHeight,
-			BlockTimestamp: block.Timestamp.Unix(),
-			TransactionID:  event.TransactionID.String(),
-			EventIndex:     uint32(event.EventIndex),
-		}).Error
-
-	case "YieldDeposited":
-		// Extract fields with fallbacks for different field names
-		userAddress := ""
-		if userField := fields["user"]; userField != nil {
-			userAddress = userField.(cadence.Address).String()
-		} else if userAddrField := fields["userAddress"]; userAddrField != nil {
-			userAddress = userAddrField.(cadence.Address).String()
-		}
+	for _, be := range batch.Events {
+		eventMeta := meta
+		eventMeta.TransactionID = be.Event.TransactionID.String()
+		eventMeta.EventIndex = uint32(be.Event.EventIndex)
 
-		protocolName := ""
-		if protocolField := fields["protocol"]; protocolField != nil {
-			protocolName = string(protocolField.(cadence.String))
-		} else if protoNameField := fields["protocolName"]; protoNameField != nil {
-			protocolName = string(protoNameField.(cadence.String))
-		}
+		if err := s.registry.Handle(s.db, be.Event.Type, be.Event.Value, eventMeta); err != nil {
+			if !strings.Contains(err.Error(), "duplicate key") {
+				log.Printf("Error storing %s event: %v", be.Name, err)
+			}
 
-		positionID := ""
-		if posIDField := fields["positionId"]; posIDField != nil {
-			positionID = string(posIDField.(cadence.String))
-		} else if marketIDField := fields["marketId"]; marketIDField != nil {
-			positionID = fmt.Sprintf("%d", uint64(marketIDField.(cadence.UInt64)))
+			continue
 		}
 
-		return db.Create(&config.FlowYieldDeposited{
-			UserAddress:    userAddress,
-			ProtocolName:   protocolName,
-			Amount:         fields["amount"].(cadence.UFix64).String(),
-			PositionID:     positionID,
-			BlockHeight:    blockHeight,
-			BlockTimestamp: block.Timestamp.Unix(),
-			TransactionID:  event.TransactionID.String(),
-			EventIndex:     uint32(event.EventIndex),
-		}).Error
-
-	case "YieldWithdrawn":
-		return db.Create(&config.FlowYieldWithdrawn{
-			MarketID:       uint64(fields["marketId"].(cadence.UInt64)),
-			Protocol:       string(fields["protocol"].(cadence.String)),
-			Amount:         fields["amount"].(cadence.UFix64).String(),
-			YieldEarned:    fields["yieldEarned"].(cadence.UFix64).String(),
-			BlockHeight:    blockHeight,
-			BlockTimestamp: block.Timestamp.Unix(),
-			TransactionID:  event.TransactionID.String(),
-			EventIndex:     uint32(event.EventIndex),
-		}).Error
+		stored++
 	}
 
-	return nil
-}
+	s.totalEvents += stored
 
-func showTableCounts(db *gorm.DB) {
-	fmt.Println("📊 Database Summary:")
-
-	tables := []struct {
-		name  string
-		model interface{}
-	}{
-		{"flow_market_createds", &config.FlowMarketCreated{}},
-		{"flow_bet_placeds", &config.FlowBetPlaced{}},
-		{"flow_market_resolveds", &config.FlowMarketResolved{}},
-		{"flow_winnings_claimeds", &config.FlowWinningsClaimed{}},
-		{"flow_yield_depositeds", &config.FlowYieldDeposited{}},
-		{"flow_yield_withdrawns", &config.FlowYieldWithdrawn{}},
+	for _, syncState := range s.syncStates {
+		syncState.LastBlockHeight = batch.BlockHeight
+		if err := s.db.Save(syncState).Error; err != nil {
+			log.Printf("Warning: Failed to update sync state for %s: %v", syncState.ContractAddress, err)
+		}
 	}
 
-	for _, table := range tables {
-		var count int64
-
-		db.Model(table.model).Count(&count)
-		fmt.Printf("  - %-25s %d rows\n", table.name+":", count)
+	if stored > 0 {
+		fmt.Printf("✓ Indexed %d events at block %d (total: %d)\n", stored, batch.BlockHeight, s.totalEvents)
 	}
+
+	return nil
 }
@@ -0,0 +1,161 @@
+// Package reorg detects Flow chain reorganizations by tracking the parent
+// hash of each block the indexer walks over, and rolls the indexed tables
+// back to the fork point when one is found.
+package reorg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/onflow/flow-go-sdk"
+	"gorm.io/gorm"
+
+	"github.com/evaafi/go-indexer/config"
+)
+
+// BlockFetcher resolves a block by height against the live chain.
+// *grpc.Client satisfies this; it's narrowed to an interface here so
+// findForkPoint can be exercised against a fake chain in tests instead of a
+// live Access API connection.
+type BlockFetcher interface {
+	GetBlockByHeight(ctx context.Context, height uint64) (*flow.Block, error)
+}
+
+// ErrDetected is returned by Guard.Observe (wrapped with the height at which
+// the divergence was noticed; the fork height is Observe's other return
+// value) when the chain has diverged from what was previously indexed.
+var ErrDetected = errors.New("chain reorg detected")
+
+// Guard tracks recently seen Flow block headers so the indexer can notice
+// when a newly observed block's parent no longer matches the header already
+// stored at the previous height.
+type Guard struct {
+	DB *gorm.DB
+
+	// Client resolves the real chain's block ID at a given height when a
+	// reorg is suspected, so findForkPoint can confirm the common ancestor
+	// against live data instead of just checking that our own stored
+	// headers agree with each other.
+	Client BlockFetcher
+
+	// RetainHeaders bounds how many historical headers are kept around for
+	// comparison; older ones are pruned once a block is recorded. Zero
+	// disables pruning.
+	RetainHeaders uint64
+}
+
+// Observe records the header for height and checks it against the header
+// already stored at height-1. If the parent doesn't match, it consults the
+// live chain to find the fork point and returns it wrapped in ErrDetected.
+//
+// Callers must invoke Observe for every block height the ingestor walks,
+// not just ones that carried a Trixy event — skipping event-less blocks
+// leaves gaps in the header table that make the height-1 lookup above miss
+// and silently disable reorg detection on any chain where events are
+// sparse.
+func (g *Guard) Observe(ctx context.Context, height uint64, blockID, parentID string, finalized bool) (uint64, error) {
+	if height > 0 {
+		var prev config.FlowBlockHeader
+
+		if err := g.DB.Where("height = ?", height-1).First(&prev).Error; err == nil && prev.BlockID != parentID {
+			forkHeight, err := g.findForkPoint(ctx, height-1)
+			if err != nil {
+				return 0, fmt.Errorf("locating fork point: %w", err)
+			}
+
+			return forkHeight, fmt.Errorf("%w: at block %d, expected parent %s but chain has %s", ErrDetected, height, prev.BlockID, parentID)
+		}
+	}
+
+	header := config.FlowBlockHeader{Height: height, BlockID: blockID, ParentID: parentID, Finalized: finalized}
+
+	if err := g.DB.Where("height = ?", height).Assign(header).FirstOrCreate(&header).Error; err != nil {
+		return 0, fmt.Errorf("recording header for block %d: %w", height, err)
+	}
+
+	g.prune(height)
+
+	return 0, nil
+}
+
+// findForkPoint walks backwards from height, fetching the live chain's
+// block at each height and comparing its ID against what we stored for
+// that height, and returns one past the first height at which they still
+// agree — i.e. the lowest height whose indexed data is no longer trusted
+// and must be rewound. Comparing stored headers against each other instead
+// of against the live chain would only confirm our own records are
+// internally consistent, not that they're still on the canonical chain, so
+// every comparison here goes through g.Client. If the trail runs out of
+// retained headers before agreement is found, it returns 0 so everything
+// gets rewound.
+func (g *Guard) findForkPoint(ctx context.Context, height uint64) (uint64, error) {
+	for h := height; h > 0; h-- {
+		var stored config.FlowBlockHeader
+
+		if err := g.DB.Where("height = ?", h).First(&stored).Error; err != nil {
+			continue
+		}
+
+		liveBlock, err := g.Client.GetBlockByHeight(ctx, h)
+		if err != nil {
+			return 0, fmt.Errorf("fetching live block %d: %w", h, err)
+		}
+
+		if stored.BlockID == liveBlock.ID.String() {
+			return h + 1, nil
+		}
+	}
+
+	return 0, nil
+}
+
+func (g *Guard) prune(height uint64) {
+	if g.RetainHeaders == 0 || height <= g.RetainHeaders {
+		return
+	}
+
+	g.DB.Where("height < ?", height-g.RetainHeaders).Delete(&config.FlowBlockHeader{})
+}
+
+// Rewind deletes every row at or past forkHeight from the Trixy event
+// tables and the header table, then rewinds every syncState so the next
+// batch re-indexes the diverged range. All configured contracts are walked
+// over the same block range in lockstep, so a single detected reorg
+// invalidates every contract's progress equally and every one of their
+// sync states is rewound together.
+func Rewind(db *gorm.DB, syncStates []*config.FlowSyncState, forkHeight uint64) error {
+	models := []interface{}{
+		&config.FlowMarketCreated{},
+		&config.FlowBetPlaced{},
+		&config.FlowMarketResolved{},
+		&config.FlowWinningsClaimed{},
+		&config.FlowYieldDeposited{},
+		&config.FlowYieldWithdrawn{},
+	}
+
+	for _, model := range models {
+		if err := db.Where("block_height >= ?", forkHeight).Delete(model).Error; err != nil {
+			return fmt.Errorf("deleting rows from fork point %d: %w", forkHeight, err)
+		}
+	}
+
+	if err := db.Where("height >= ?", forkHeight).Delete(&config.FlowBlockHeader{}).Error; err != nil {
+		return fmt.Errorf("deleting stale headers from fork point %d: %w", forkHeight, err)
+	}
+
+	rewoundHeight := forkHeight
+	if forkHeight > 0 {
+		rewoundHeight = forkHeight - 1
+	}
+
+	for _, syncState := range syncStates {
+		syncState.LastBlockHeight = rewoundHeight
+
+		if err := db.Save(syncState).Error; err != nil {
+			return fmt.Errorf("saving rewound sync state for %s: %w", syncState.ContractAddress, err)
+		}
+	}
+
+	return nil
+}
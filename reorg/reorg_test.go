@@ -0,0 +1,101 @@
+package reorg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/onflow/flow-go-sdk"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/evaafi/go-indexer/config"
+)
+
+// fakeChain is a BlockFetcher backed by an in-memory map of height -> block
+// ID, standing in for the live Access API so findForkPoint's walk-back can
+// be driven without a real Flow connection.
+type fakeChain map[uint64]flow.Identifier
+
+func (c fakeChain) GetBlockByHeight(_ context.Context, height uint64) (*flow.Block, error) {
+	blockID, ok := c[height]
+	if !ok {
+		return nil, fmt.Errorf("no block at height %d", height)
+	}
+
+	return &flow.Block{BlockHeader: flow.BlockHeader{ID: blockID}}, nil
+}
+
+// id derives a deterministic, valid 32-byte Identifier from a small
+// distinguishing number so "old" and "new" fork blocks never collide.
+func id(n uint64) flow.Identifier {
+	return flow.HexToID(fmt.Sprintf("%064x", n))
+}
+
+const newForkOffset = 1_000_000
+
+func oldID(height uint64) flow.Identifier { return id(height) }
+func newID(height uint64) flow.Identifier { return id(newForkOffset + height) }
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("opening test db: %v", err)
+	}
+
+	if err := db.AutoMigrate(&config.FlowBlockHeader{}); err != nil {
+		t.Fatalf("migrating test db: %v", err)
+	}
+
+	return db
+}
+
+// TestObserveDetectsDeepReorg simulates a reorg whose fork point is several
+// blocks behind the height at which it's first noticed, and checks that
+// Observe reports the real fork point (the first height past the common
+// ancestor) rather than the height it happened to be looking at when the
+// mismatch surfaced.
+func TestObserveDetectsDeepReorg(t *testing.T) {
+	db := newTestDB(t)
+
+	// Old chain: heights 0-10, block h's ID is oldID(h), parent oldID(h-1).
+	for h := uint64(0); h <= 10; h++ {
+		parent := oldID(0)
+		if h > 0 {
+			parent = oldID(h - 1)
+		}
+
+		header := config.FlowBlockHeader{
+			Height:   h,
+			BlockID:  oldID(h).String(),
+			ParentID: parent.String(),
+		}
+		if err := db.Create(&header).Error; err != nil {
+			t.Fatalf("seeding header %d: %v", h, err)
+		}
+	}
+
+	// Live chain diverged starting at height 7: heights 7-10 are a new fork,
+	// heights 0-6 are the shared common ancestor.
+	live := fakeChain{}
+	for h := uint64(0); h <= 6; h++ {
+		live[h] = oldID(h)
+	}
+	for h := uint64(7); h <= 10; h++ {
+		live[h] = newID(h)
+	}
+
+	guard := &Guard{DB: db, Client: live}
+
+	forkHeight, err := guard.Observe(context.Background(), 11, newID(11).String(), newID(10).String(), true)
+	if !errors.Is(err, ErrDetected) {
+		t.Fatalf("expected ErrDetected, got %v", err)
+	}
+
+	if forkHeight != 7 {
+		t.Fatalf("expected fork point 7, got %d", forkHeight)
+	}
+}
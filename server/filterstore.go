@@ -0,0 +1,109 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// filterState is the server-side state behind one trixy_newFilter call: the
+// criteria it was created with, and how far trixy_getFilterChanges has
+// already delivered.
+type filterState struct {
+	filter     Filter
+	lastHeight uint64
+
+	// lastIndex is the EventIndex last delivered at lastHeight, or -1 if
+	// nothing has been delivered yet. -1 (rather than relying on the zero
+	// value) matters because a fresh filter's lastHeight already equals
+	// FromBlock, so without it the very first event at FromBlock with
+	// EventIndex 0 would look like it was already delivered and get
+	// dropped.
+	lastIndex int64
+}
+
+// FilterStore holds every filter created via trixy_newFilter, mirroring
+// the poll-for-changes model of eth_newFilter/eth_getFilterChanges.
+type FilterStore struct {
+	db *gorm.DB
+
+	mu      sync.Mutex
+	filters map[string]*filterState
+}
+
+// NewFilterStore creates a FilterStore backed by db.
+func NewFilterStore(db *gorm.DB) *FilterStore {
+	return &FilterStore{db: db, filters: make(map[string]*filterState)}
+}
+
+// New registers f and returns an opaque filter ID for later
+// trixy_getFilterChanges calls.
+func (s *FilterStore) New(f Filter) (string, error) {
+	id, err := randomID()
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	s.filters[id] = &filterState{filter: f, lastHeight: f.FromBlock, lastIndex: -1}
+	s.mu.Unlock()
+
+	return id, nil
+}
+
+// Changes returns every Log matching filter id that wasn't already
+// returned by a previous call, advancing the filter's cursor past them.
+func (s *FilterStore) Changes(id string) ([]Log, error) {
+	s.mu.Lock()
+	state, ok := s.filters[id]
+	s.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown filter id %q", id)
+	}
+
+	s.mu.Lock()
+	query := state.filter
+	query.FromBlock = state.lastHeight
+	s.mu.Unlock()
+
+	logs, err := getLogs(s.db, query)
+	if err != nil {
+		return nil, err
+	}
+
+	fresh := logs[:0:0]
+
+	for _, log := range logs {
+		if log.BlockHeight == state.lastHeight && int64(log.EventIndex) <= state.lastIndex {
+			continue
+		}
+
+		fresh = append(fresh, log)
+	}
+
+	if len(fresh) == 0 {
+		return fresh, nil
+	}
+
+	last := fresh[len(fresh)-1]
+
+	s.mu.Lock()
+	state.lastHeight = last.BlockHeight
+	state.lastIndex = int64(last.EventIndex)
+	s.mu.Unlock()
+
+	return fresh, nil
+}
+
+func randomID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return "0x" + hex.EncodeToString(buf), nil
+}
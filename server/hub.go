@@ -0,0 +1,68 @@
+package server
+
+import (
+	"sync"
+
+	"github.com/evaafi/go-indexer/handlers"
+)
+
+// hubBacklog bounds each subscriber's buffered channel. A subscriber that
+// falls this far behind is dropped rather than allowed to block ingestion.
+const hubBacklog = 256
+
+// Hub is an in-process pub/sub of stored Logs. It implements
+// handlers.Publisher so a HandlerRegistry can feed it directly, and hands
+// every Log to any live websocket subscribers (for trixy_subscribe).
+// trixy_getFilterChanges does not go through the Hub: FilterStore reads
+// from the database.
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[chan Log]struct{}
+}
+
+// NewHub creates an empty Hub.
+func NewHub() *Hub {
+	return &Hub{subscribers: make(map[chan Log]struct{})}
+}
+
+// Publish implements handlers.Publisher.
+func (h *Hub) Publish(eventType, name string, fields map[string]any, meta handlers.DecodedEvent) {
+	log := newLog(eventType, name, fields, meta)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subscribers {
+		select {
+		case ch <- log:
+		default:
+			// Subscriber is too far behind; drop it rather than block
+			// indexing on a slow client.
+			delete(h.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe registers a new listener and returns a channel of every Log
+// published from now on, plus an unsubscribe func the caller must call
+// when done.
+func (h *Hub) Subscribe() (<-chan Log, func()) {
+	ch := make(chan Log, hubBacklog)
+
+	h.mu.Lock()
+	h.subscribers[ch] = struct{}{}
+	h.mu.Unlock()
+
+	unsubscribe := func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		if _, ok := h.subscribers[ch]; ok {
+			delete(h.subscribers, ch)
+			close(ch)
+		}
+	}
+
+	return ch, unsubscribe
+}
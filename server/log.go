@@ -0,0 +1,89 @@
+// Package server exposes the indexer's stored events over HTTP without
+// requiring downstream consumers to query Postgres directly: a JSON-RPC
+// API for one-shot and polling queries, answered from the database, and a
+// websocket subscription for near-real-time delivery, backed by an
+// in-process pub/sub fed by the handlers package as it stores each event.
+package server
+
+import (
+	"github.com/evaafi/go-indexer/handlers"
+)
+
+// Log is the public shape of a stored Trixy event: enough to filter on
+// (EventName, Fields) and to order/paginate by (BlockHeight, EventIndex),
+// without exposing the Postgres schema behind it.
+type Log struct {
+	EventType      string         `json:"eventType"`
+	EventName      string         `json:"eventName"`
+	Fields         map[string]any `json:"fields"`
+	BlockHeight    uint64         `json:"blockHeight"`
+	BlockTimestamp int64          `json:"blockTimestamp"`
+	BlockID        string         `json:"blockId"`
+	Finalized      bool           `json:"finalized"`
+	TransactionID  string         `json:"transactionId"`
+	EventIndex     uint32         `json:"eventIndex"`
+}
+
+func newLog(eventType, name string, fields map[string]any, meta handlers.DecodedEvent) Log {
+	return Log{
+		EventType:      eventType,
+		EventName:      name,
+		Fields:         fields,
+		BlockHeight:    meta.BlockHeight,
+		BlockTimestamp: meta.BlockTimestamp,
+		BlockID:        meta.BlockID,
+		Finalized:      meta.Finalized,
+		TransactionID:  meta.TransactionID,
+		EventIndex:     meta.EventIndex,
+	}
+}
+
+// Filter selects a subset of Logs. A nil pointer field means "don't filter
+// on this"; MarketID and ToBlock are pointers because 0 is a valid market
+// ID and an unbounded ToBlock both need to be distinguishable from "unset".
+type Filter struct {
+	EventName string  `json:"eventName"`
+	MarketID  *uint64 `json:"marketId"`
+	User      string  `json:"user"`
+	Protocol  string  `json:"protocol"`
+	FromBlock uint64  `json:"fromBlock"`
+	ToBlock   *uint64 `json:"toBlock"`
+}
+
+// Matches reports whether log satisfies every constraint f sets.
+func (f Filter) Matches(log Log) bool {
+	if f.EventName != "" && f.EventName != log.EventName {
+		return false
+	}
+
+	if log.BlockHeight < f.FromBlock {
+		return false
+	}
+
+	if f.ToBlock != nil && log.BlockHeight > *f.ToBlock {
+		return false
+	}
+
+	if f.MarketID != nil {
+		marketID, ok := log.Fields["marketId"].(uint64)
+		if !ok || marketID != *f.MarketID {
+			return false
+		}
+	}
+
+	if f.User != "" {
+		user, _ := log.Fields["user"].(string)
+		if user != f.User {
+			return false
+		}
+	}
+
+	if f.Protocol != "" {
+		protocol, _ := log.Fields["protocol"].(string)
+		if protocol != f.Protocol {
+			return false
+		}
+	}
+
+	return true
+}
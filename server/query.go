@@ -0,0 +1,245 @@
+package server
+
+import (
+	"sort"
+
+	"gorm.io/gorm"
+
+	"github.com/evaafi/go-indexer/config"
+)
+
+// source describes how to turn one Flow event table into Logs for
+// trixy_getLogs. Each Trixy event has its own model (and its own idea of
+// which column holds "user"/"protocol"), so sources hand-map those columns
+// rather than relying on struct reflection.
+type source struct {
+	eventType string
+	query     func(db *gorm.DB, f Filter) *gorm.DB
+	toLog     func(row map[string]any) Log
+}
+
+func applyBlockRange(db *gorm.DB, f Filter) *gorm.DB {
+	db = db.Where("block_height >= ?", f.FromBlock)
+	if f.ToBlock != nil {
+		db = db.Where("block_height <= ?", *f.ToBlock)
+	}
+
+	return db
+}
+
+var sources = []source{
+	{
+		eventType: "MarketCreated",
+		query: func(db *gorm.DB, f Filter) *gorm.DB {
+			db = applyBlockRange(db.Model(&config.FlowMarketCreated{}), f)
+			if f.MarketID != nil {
+				db = db.Where("market_id = ?", *f.MarketID)
+			}
+
+			if f.User != "" {
+				db = db.Where("creator = ?", f.User)
+			}
+
+			if f.Protocol != "" {
+				db = db.Where("yield_protocol = ?", f.Protocol)
+			}
+
+			return db
+		},
+		toLog: func(row map[string]any) Log {
+			return Log{
+				EventName: "MarketCreated",
+				Fields: map[string]any{
+					"marketId": row["market_id"],
+					"user":     row["creator"],
+					"protocol": row["yield_protocol"],
+				},
+			}
+		},
+	},
+	{
+		eventType: "BetPlaced",
+		query: func(db *gorm.DB, f Filter) *gorm.DB {
+			db = applyBlockRange(db.Model(&config.FlowBetPlaced{}), f)
+			if f.MarketID != nil {
+				db = db.Where("market_id = ?", *f.MarketID)
+			}
+
+			if f.User != "" {
+				db = db.Where("\"user\" = ?", f.User)
+			}
+
+			return db
+		},
+		toLog: func(row map[string]any) Log {
+			return Log{
+				EventName: "BetPlaced",
+				Fields: map[string]any{
+					"marketId": row["market_id"],
+					"user":     row["user"],
+				},
+			}
+		},
+	},
+	{
+		eventType: "MarketResolved",
+		query: func(db *gorm.DB, f Filter) *gorm.DB {
+			db = applyBlockRange(db.Model(&config.FlowMarketResolved{}), f)
+			if f.MarketID != nil {
+				db = db.Where("market_id = ?", *f.MarketID)
+			}
+
+			return db
+		},
+		toLog: func(row map[string]any) Log {
+			return Log{
+				EventName: "MarketResolved",
+				Fields: map[string]any{
+					"marketId": row["market_id"],
+				},
+			}
+		},
+	},
+	{
+		eventType: "WinningsClaimed",
+		query: func(db *gorm.DB, f Filter) *gorm.DB {
+			db = applyBlockRange(db.Model(&config.FlowWinningsClaimed{}), f)
+			if f.MarketID != nil {
+				db = db.Where("market_id = ?", *f.MarketID)
+			}
+
+			if f.User != "" {
+				db = db.Where("\"user\" = ?", f.User)
+			}
+
+			return db
+		},
+		toLog: func(row map[string]any) Log {
+			return Log{
+				EventName: "WinningsClaimed",
+				Fields: map[string]any{
+					"marketId": row["market_id"],
+					"user":     row["user"],
+				},
+			}
+		},
+	},
+	{
+		eventType: "YieldDeposited",
+		query: func(db *gorm.DB, f Filter) *gorm.DB {
+			db = applyBlockRange(db.Model(&config.FlowYieldDeposited{}), f)
+			if f.User != "" {
+				db = db.Where("user_address = ?", f.User)
+			}
+
+			if f.Protocol != "" {
+				db = db.Where("protocol_name = ?", f.Protocol)
+			}
+
+			return db
+		},
+		toLog: func(row map[string]any) Log {
+			return Log{
+				EventName: "YieldDeposited",
+				Fields: map[string]any{
+					"user":     row["user_address"],
+					"protocol": row["protocol_name"],
+				},
+			}
+		},
+	},
+	{
+		eventType: "YieldWithdrawn",
+		query: func(db *gorm.DB, f Filter) *gorm.DB {
+			db = applyBlockRange(db.Model(&config.FlowYieldWithdrawn{}), f)
+			if f.MarketID != nil {
+				db = db.Where("market_id = ?", *f.MarketID)
+			}
+
+			if f.Protocol != "" {
+				db = db.Where("protocol = ?", f.Protocol)
+			}
+
+			return db
+		},
+		toLog: func(row map[string]any) Log {
+			return Log{
+				EventName: "YieldWithdrawn",
+				Fields: map[string]any{
+					"marketId": row["market_id"],
+					"protocol": row["protocol"],
+				},
+			}
+		},
+	},
+}
+
+// getLogs runs f against every source whose EventName matches (or all of
+// them, if f.EventName is unset), returning Logs ordered by block height.
+func getLogs(db *gorm.DB, f Filter) ([]Log, error) {
+	var logs []Log
+
+	for _, src := range sources {
+		if f.EventName != "" && f.EventName != src.eventType {
+			continue
+		}
+
+		var rows []map[string]any
+		if err := src.query(db, f).Order("block_height asc, event_index asc").Find(&rows).Error; err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			log := src.toLog(row)
+			log.EventType = src.eventType
+			log.BlockHeight, _ = toUint64(row["block_height"])
+			log.BlockTimestamp, _ = toInt64(row["block_timestamp"])
+			log.BlockID, _ = row["block_id"].(string)
+			log.Finalized, _ = row["finalized"].(bool)
+			log.TransactionID, _ = row["transaction_id"].(string)
+
+			eventIndex, _ := toUint64(row["event_index"])
+			log.EventIndex = uint32(eventIndex)
+
+			logs = append(logs, log)
+		}
+	}
+
+	// Each source query is sorted within itself, but sources are appended in
+	// a fixed iteration order, not merged — re-sort the combined result so
+	// callers (and FilterStore.Changes' high-water mark) see one globally
+	// chronological stream.
+	sort.Slice(logs, func(i, j int) bool {
+		if logs[i].BlockHeight != logs[j].BlockHeight {
+			return logs[i].BlockHeight < logs[j].BlockHeight
+		}
+
+		return logs[i].EventIndex < logs[j].EventIndex
+	})
+
+	return logs, nil
+}
+
+func toUint64(v any) (uint64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return uint64(n), true
+	case uint64:
+		return n, true
+	case int:
+		return uint64(n), true
+	default:
+		return 0, false
+	}
+}
+
+func toInt64(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case int:
+		return int64(n), true
+	default:
+		return 0, false
+	}
+}
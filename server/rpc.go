@@ -0,0 +1,111 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// rpcRequest is a JSON-RPC 2.0 request. Trixy only implements the handful
+// of methods below, so batching isn't supported.
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// rpcHandler serves trixy_getLogs, trixy_newFilter and
+// trixy_getFilterChanges over HTTP POST, mirroring the shape of Ethereum's
+// JSON-RPC log filter API.
+type rpcHandler struct {
+	db      *gorm.DB
+	filters *FilterStore
+}
+
+func (h *rpcHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req rpcRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON-RPC request", http.StatusBadRequest)
+		return
+	}
+
+	resp := rpcResponse{JSONRPC: "2.0", ID: req.ID}
+
+	result, err := h.dispatch(req.Method, req.Params)
+	if err != nil {
+		if rpcErr, ok := err.(*rpcError); ok {
+			resp.Error = rpcErr
+		} else {
+			resp.Error = &rpcError{Code: -32000, Message: err.Error()}
+		}
+	} else {
+		resp.Result = result
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (h *rpcHandler) dispatch(method string, params json.RawMessage) (any, error) {
+	switch method {
+	case "trixy_getLogs":
+		var filter Filter
+		if err := decodeParams(params, &filter); err != nil {
+			return nil, err
+		}
+
+		return getLogs(h.db, filter)
+
+	case "trixy_newFilter":
+		var filter Filter
+		if err := decodeParams(params, &filter); err != nil {
+			return nil, err
+		}
+
+		return h.filters.New(filter)
+
+	case "trixy_getFilterChanges":
+		var args struct {
+			ID string `json:"id"`
+		}
+		if err := decodeParams(params, &args); err != nil {
+			return nil, err
+		}
+
+		return h.filters.Changes(args.ID)
+
+	default:
+		return nil, errUnknownMethod(method)
+	}
+}
+
+// decodeParams accepts both "params": {...} and the more common
+// JSON-RPC-by-spec "params": [{...}] single-element array form.
+func decodeParams(params json.RawMessage, out any) error {
+	var asArray []json.RawMessage
+	if err := json.Unmarshal(params, &asArray); err == nil && len(asArray) > 0 {
+		return json.Unmarshal(asArray[0], out)
+	}
+
+	return json.Unmarshal(params, out)
+}
+
+func errUnknownMethod(method string) error {
+	return &rpcError{Code: -32601, Message: "unknown method " + method}
+}
+
+func (e *rpcError) Error() string { return e.Message }
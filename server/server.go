@@ -0,0 +1,31 @@
+package server
+
+import (
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// Server is the indexer's read API: JSON-RPC over HTTP at /rpc, and a
+// websocket log subscription at /ws.
+type Server struct {
+	addr string
+	mux  *http.ServeMux
+}
+
+// NewServer builds a Server backed by db for queries and hub for live
+// subscriptions. hub must already be wired as the HandlerRegistry's
+// Publisher for /ws to see anything.
+func NewServer(addr string, db *gorm.DB, hub *Hub) *Server {
+	mux := http.NewServeMux()
+	mux.Handle("/rpc", &rpcHandler{db: db, filters: NewFilterStore(db)})
+	mux.Handle("/ws", &wsHandler{hub: hub})
+
+	return &Server{addr: addr, mux: mux}
+}
+
+// ListenAndServe blocks serving the API until the process exits or the
+// listener errors.
+func (s *Server) ListenAndServe() error {
+	return http.ListenAndServe(s.addr, s.mux)
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	// Trixy is meant to be embedded behind a reverse proxy that already
+	// enforces its own origin policy; the indexer itself doesn't know
+	// which frontends are allowed to call it.
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// subscribeRequest is the single message a client sends after connecting:
+// {"method": "trixy_subscribe", "params": ["logs", {...filter}]}.
+type subscribeRequest struct {
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+// wsHandler upgrades to a websocket and streams newly indexed Logs
+// matching the client's filter, fed by the Hub as HandlerRegistry stores
+// each event.
+type wsHandler struct {
+	hub *Hub
+}
+
+func (h *wsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("websocket upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	var req subscribeRequest
+	if err := conn.ReadJSON(&req); err != nil {
+		return
+	}
+
+	if req.Method != "trixy_subscribe" || len(req.Params) < 2 {
+		_ = conn.WriteJSON(rpcResponse{JSONRPC: "2.0", Error: &rpcError{Code: -32602, Message: "expected trixy_subscribe(\"logs\", filter)"}})
+		return
+	}
+
+	var topic string
+	if err := json.Unmarshal(req.Params[0], &topic); err != nil || topic != "logs" {
+		_ = conn.WriteJSON(rpcResponse{JSONRPC: "2.0", Error: &rpcError{Code: -32602, Message: "unsupported subscription topic"}})
+		return
+	}
+
+	var filter Filter
+	if err := json.Unmarshal(req.Params[1], &filter); err != nil {
+		_ = conn.WriteJSON(rpcResponse{JSONRPC: "2.0", Error: &rpcError{Code: -32602, Message: "invalid filter"}})
+		return
+	}
+
+	logs, unsubscribe := h.hub.Subscribe()
+	defer unsubscribe()
+
+	for entry := range logs {
+		if !filter.Matches(entry) {
+			continue
+		}
+
+		if err := conn.WriteJSON(entry); err != nil {
+			return
+		}
+	}
+}